@@ -0,0 +1,181 @@
+// Copyright 2015 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+)
+
+// Alias is a single redirect from the old path From to the target URL To.
+// Callers build the list passed to WriteAliases themselves; this package
+// does not collect aliases from front matter or from PaginateAliasPath.
+type Alias struct {
+	From string
+	To   string
+}
+
+// AliasWriter turns a full set of aliases into one artifact in the publish
+// dir. Which writers run is set by the format names passed to WriteAliases,
+// e.g. []string{"html", "netlify"}; no config key is read for them.
+type AliasWriter interface {
+	// Filename is the path, relative to the publish dir, the writer's output
+	// should be written to. HTMLMetaRefresh returns "" since it instead
+	// writes one stub per alias via WriteAlias.
+	Filename() string
+
+	// Write renders all aliases to w. Called once per build for writers with
+	// a non-empty Filename.
+	Write(w io.Writer, aliases []Alias) error
+}
+
+// aliasWriters maps each alias format name to its implementation.
+var aliasWriters = map[string]AliasWriter{
+	"html":    HTMLMetaRefresh{},
+	"netlify": NetlifyRedirects{},
+	"nginx":   NginxRewrites{},
+	"apache":  ApacheHtaccess{},
+	"json":    JSONManifest{},
+}
+
+// AliasWriterByName looks up the AliasWriter registered under a single
+// format name.
+func AliasWriterByName(name string) (AliasWriter, error) {
+	w, ok := aliasWriters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown alias format %q", name)
+	}
+	return w, nil
+}
+
+// WriteAliases runs every writer named in formats over aliases, writing each
+// one's output under publishDir.
+func WriteAliases(fs afero.Fs, publishDir string, formats []string, aliases []Alias) error {
+	for _, name := range formats {
+		w, err := AliasWriterByName(name)
+		if err != nil {
+			return err
+		}
+		if w.Filename() == "" {
+			// e.g. HTMLMetaRefresh, which writes one stub per alias rather
+			// than a single collected file.
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := w.Write(&buf, aliases); err != nil {
+			return err
+		}
+		if err := WriteToDisk(fpb.Join(publishDir, w.Filename()), &buf, fs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HTMLMetaRefresh is the original Hugo alias behaviour: a standalone HTML
+// page per alias with a `<meta http-equiv="refresh">` pointing at the real
+// page. It has no collected Filename; WriteAlias below writes its stub
+// directly for a single alias, the same way the site build already did
+// before AliasWriter existed.
+type HTMLMetaRefresh struct{}
+
+func (HTMLMetaRefresh) Filename() string { return "" }
+
+func (HTMLMetaRefresh) Write(w io.Writer, aliases []Alias) error {
+	return nil
+}
+
+// WriteAlias renders the meta-refresh stub for a single alias.
+func (HTMLMetaRefresh) WriteAlias(w io.Writer, permalink string) error {
+	_, err := fmt.Fprintf(w, aliasHTMLTemplate, permalink, permalink, permalink)
+	return err
+}
+
+const aliasHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+  <title>%s</title>
+  <link rel="canonical" href="%s"/>
+  <meta name="robots" content="noindex">
+  <meta charset="utf-8" />
+  <meta http-equiv="refresh" content="0; url=%s" />
+</head>
+</html>`
+
+// NetlifyRedirects writes a single `_redirects` file understood by Netlify
+// (and Vercel/Cloudflare Pages, which use the same format):
+// `from  to  status` lines, one per alias.
+type NetlifyRedirects struct{}
+
+func (NetlifyRedirects) Filename() string { return "_redirects" }
+
+func (NetlifyRedirects) Write(w io.Writer, aliases []Alias) error {
+	for _, a := range aliases {
+		if _, err := fmt.Fprintf(w, "%s  %s  301\n", a.From, a.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NginxRewrites writes a snippet of `rewrite` directives suitable for
+// `include`-ing into an nginx `server` block.
+type NginxRewrites struct{}
+
+func (NginxRewrites) Filename() string { return "nginx.conf" }
+
+func (NginxRewrites) Write(w io.Writer, aliases []Alias) error {
+	for _, a := range aliases {
+		if _, err := fmt.Fprintf(w, "rewrite ^%s$ %s permanent;\n", a.From, a.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApacheHtaccess writes mod_alias `Redirect` directives to a `.htaccess`
+// file.
+type ApacheHtaccess struct{}
+
+func (ApacheHtaccess) Filename() string { return ".htaccess" }
+
+func (ApacheHtaccess) Write(w io.Writer, aliases []Alias) error {
+	for _, a := range aliases {
+		if _, err := fmt.Fprintf(w, "Redirect 301 %s %s\n", a.From, a.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSONManifest writes the full alias list as a single `aliases.json`, for
+// deploy targets (or client-side routers) that want to do their own
+// redirect handling.
+type JSONManifest struct{}
+
+func (JSONManifest) Filename() string { return "aliases.json" }
+
+func (JSONManifest) Write(w io.Writer, aliases []Alias) error {
+	b, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
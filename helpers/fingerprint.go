@@ -0,0 +1,142 @@
+// Copyright 2015 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+
+	"github.com/OneOfOne/xxhash"
+	"github.com/spf13/afero"
+)
+
+// Fingerprint hashes content with the given algorithm ("sha256", "sha384" or
+// "xxhash") and returns path with the hex-encoded hash spliced in before the
+// extension, e.g. Fingerprint("style.css", b, "sha256") -> "style.abc123.css".
+// AssetManifest.FingerprintAndRecord below builds on it; there is no
+// "fingerprint" template function, and static files are not fingerprinted.
+func Fingerprint(path string, content []byte, algo string) (string, error) {
+	sum, err := hashContent(content, algo)
+	if err != nil {
+		return "", err
+	}
+
+	dir, base := fpb.Dir(path), fpb.Base(path)
+	name, ext := fileAndExt(base, fpb)
+	ext = strings.TrimPrefix(ext, ".")
+	hashed := hex.EncodeToString(sum)
+
+	var fingerprinted string
+	if ext == "" {
+		fingerprinted = name + "." + hashed
+	} else {
+		fingerprinted = fmt.Sprintf("%s.%s.%s", name, hashed, ext)
+	}
+
+	if dir == "." {
+		return fingerprinted, nil
+	}
+	return fpb.Join(dir, fingerprinted), nil
+}
+
+// Integrity returns a Subresource Integrity attribute value, e.g.
+// "sha384-oqVuAfXRKap7fdgcCY5uykM6+R9GqQ8K/uxy9rx7HNQlGYl1kPzQho1wx4JwY8wC",
+// suitable for the integrity="..." attribute of a <link> or <script> tag.
+func Integrity(content []byte, algo string) (string, error) {
+	sum, err := hashContent(content, algo)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s", algo, base64.StdEncoding.EncodeToString(sum)), nil
+}
+
+func hashContent(content []byte, algo string) ([]byte, error) {
+	var h hash.Hash
+
+	switch algo {
+	case "sha256", "":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	case "xxhash":
+		h = xxhash.New64()
+	default:
+		return nil, fmt.Errorf("unsupported fingerprint algorithm %q", algo)
+	}
+
+	h.Write(content)
+	return h.Sum(nil), nil
+}
+
+// AssetManifest records the mapping of original asset paths to their
+// fingerprinted equivalents, so callers can look up the hashed name for an
+// original path without hashing its content again. It is safe for
+// concurrent use.
+type AssetManifest struct {
+	mu      sync.RWMutex
+	Entries map[string]string `json:"entries"`
+}
+
+// NewAssetManifest creates an empty AssetManifest.
+func NewAssetManifest() *AssetManifest {
+	return &AssetManifest{Entries: make(map[string]string)}
+}
+
+// Add records that original now resolves to hashed.
+func (m *AssetManifest) Add(original, hashed string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Entries[original] = hashed
+}
+
+// FingerprintAndRecord fingerprints content under path with algo and records
+// the original->hashed mapping in m, returning the hashed path. Paths
+// fingerprinted by calling Fingerprint directly are not recorded in any
+// manifest.
+func (m *AssetManifest) FingerprintAndRecord(path string, content []byte, algo string) (string, error) {
+	hashed, err := Fingerprint(path, content, algo)
+	if err != nil {
+		return "", err
+	}
+	m.Add(path, hashed)
+	return hashed, nil
+}
+
+// Lookup returns the hashed path for original, if known.
+func (m *AssetManifest) Lookup(original string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	hashed, ok := m.Entries[original]
+	return hashed, ok
+}
+
+// WriteTo writes the manifest to disk as JSON at path, using fs so the call
+// participates in the same filesystem abstraction as the rest of the build.
+func (m *AssetManifest) WriteTo(path string, fs afero.Fs) error {
+	m.mu.RLock()
+	b, err := json.MarshalIndent(m, "", "  ")
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return WriteToDisk(path, bytes.NewReader(b), fs)
+}
@@ -0,0 +1,97 @@
+// Copyright 2015 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	content := []byte("body { color: red; }")
+
+	for i, test := range []struct {
+		path    string
+		algo    string
+		prefix  string
+		suffix  string
+		wantErr bool
+	}{
+		{"style.css", "sha256", "style.", ".css", false},
+		{"css/style.css", "sha256", "css/style.", ".css", false},
+		{"README", "sha256", "README.", "", false},
+		{"style.css", "bogus", "", "", true},
+	} {
+		got, err := Fingerprint(test.path, content, test.algo)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("[%d] expected error for algo %q, got none", i, test.algo)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[%d] Fingerprint(%q) returned error: %s", i, test.path, err)
+		}
+		if !strings.HasPrefix(got, test.prefix) {
+			t.Errorf("[%d] Fingerprint(%q) = %q, want prefix %q", i, test.path, got, test.prefix)
+		}
+		if test.suffix != "" && !strings.HasSuffix(got, test.suffix) {
+			t.Errorf("[%d] Fingerprint(%q) = %q, want suffix %q", i, test.path, got, test.suffix)
+		}
+		if strings.Contains(got, "..") {
+			t.Errorf("[%d] Fingerprint(%q) = %q, contains a double dot", i, test.path, got)
+		}
+	}
+}
+
+func TestFingerprintDeterministic(t *testing.T) {
+	content := []byte("var x = 1;")
+	a, err := Fingerprint("app.js", content, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Fingerprint("app.js", content, "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("Fingerprint is not deterministic: %q != %q", a, b)
+	}
+}
+
+func TestAssetManifestFingerprintAndRecord(t *testing.T) {
+	m := NewAssetManifest()
+	hashed, err := m.FingerprintAndRecord("css/style.css", []byte("a{}"), "sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := m.Lookup("css/style.css")
+	if !ok {
+		t.Fatalf("expected css/style.css to be recorded in the manifest")
+	}
+	if got != hashed {
+		t.Errorf("Lookup returned %q, want %q", got, hashed)
+	}
+}
+
+func TestIntegrity(t *testing.T) {
+	sri, err := Integrity([]byte("a{}"), "sha384")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sri, "sha384-") {
+		t.Errorf("Integrity() = %q, want sha384- prefix", sri)
+	}
+}
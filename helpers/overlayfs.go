@@ -0,0 +1,224 @@
+// Copyright 2015 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// OverlayFs is a single afero.Fs that transparently resolves paths across the
+// site directory, one or more themes and any module dependencies, in that
+// priority order. It replaces the old pattern of callers gluing together
+// GetStaticDirPath/GetThemeStaticDirPath/... with string prefix checks: a
+// single fs.Open("static/css/site.css") now finds the first layer that has
+// the file.
+//
+// Layers are stacked with afero.NewCopyOnWriteFs so writes (e.g. generated
+// assets) always land in the site layer without touching the read-only
+// theme/module layers underneath. Lookups are not cached across the whole
+// OverlayFs lifetime: a single build calls RelPath/Open freely, but an
+// OverlayFs is cheap to rebuild and callers that live across multiple builds
+// (e.g. "hugo server" watching files) should build a fresh one per build
+// rather than rely on an internal cache that would otherwise go stale.
+type OverlayFs struct {
+	afero.Fs
+
+	// layers records the source root for each layer, outermost (highest
+	// priority) first, so RelPath can report which one a path came from.
+	layers []overlayLayer
+}
+
+type overlayLayer struct {
+	name string // "site", "theme:<name>" or "module:<path>"
+	root string
+}
+
+// NewOverlayFs builds an OverlayFs from site (the project's own directory),
+// themes (one or more theme roots, in priority order) and modules (dependency
+// roots, lowest priority). Later layers are only consulted for paths the
+// earlier ones don't have.
+func NewOverlayFs(base afero.Fs, site string, themes []string, modules []string) *OverlayFs {
+	roots := make([]overlayLayer, 0, 1+len(themes)+len(modules))
+	roots = append(roots, overlayLayer{name: "site", root: site})
+	for _, t := range themes {
+		roots = append(roots, overlayLayer{name: "theme:" + t, root: t})
+	}
+	for _, m := range modules {
+		roots = append(roots, overlayLayer{name: "module:" + m, root: m})
+	}
+
+	var fs afero.Fs = afero.NewBasePathFs(base, roots[len(roots)-1].root)
+	for i := len(roots) - 2; i >= 0; i-- {
+		layerFs := afero.NewBasePathFs(base, roots[i].root)
+		fs = afero.NewCopyOnWriteFs(fs, layerFs)
+	}
+
+	return &OverlayFs{
+		Fs:     fs,
+		layers: roots,
+	}
+}
+
+// Mount describes a single entry of the "mounts" config key, e.g.
+// {source="../shared/img", target="static/img"}: everything under source is
+// made visible under target inside the overlay, in addition to the normal
+// site/theme/module layers.
+type Mount struct {
+	Source string
+	Target string
+}
+
+// Mount adds source as an extra, lowest-priority, read-only layer visible
+// only under target. It is consulted only for paths the existing
+// site/theme/module stack doesn't already have, and writes never land here:
+// source is passed as the base argument to afero.NewCopyOnWriteFs, with the
+// existing stack as the layer, so the site layer keeps being the sole write
+// target. Later calls to Mount are themselves shadowed by earlier ones.
+func (ofs *OverlayFs) Mount(base afero.Fs, m Mount) {
+	mounted := &mountPrefixFs{Fs: afero.NewBasePathFs(base, m.Source), prefix: m.Target}
+	ofs.Fs = afero.NewCopyOnWriteFs(mounted, ofs.Fs)
+	ofs.layers = append(ofs.layers, overlayLayer{name: "mount:" + m.Target, root: m.Source})
+}
+
+// mountPrefixFs makes an afero.Fs rooted elsewhere answer as if it were
+// rooted under prefix, e.g. a fs rooted at "../shared/img" answering
+// Open("static/img/logo.png") by stripping "static/img" and delegating.
+// Every method that takes a path is overridden so Stat/OpenFile/etc. see the
+// same stripped path Open does - afero's layered filesystems call Stat
+// before Open on every read, so leaving it unoverridden means the mount is
+// never actually consulted.
+type mountPrefixFs struct {
+	afero.Fs
+	prefix string
+}
+
+func (m *mountPrefixFs) stripPrefix(name string) (string, error) {
+	rel := strings.TrimPrefix(name, FilePathSeparator)
+	if rel == m.prefix {
+		return "", nil
+	}
+	if strings.HasPrefix(rel, m.prefix+FilePathSeparator) {
+		return strings.TrimPrefix(rel, m.prefix+FilePathSeparator), nil
+	}
+	return "", &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+func (m *mountPrefixFs) Open(name string) (afero.File, error) {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Fs.Open(rel)
+}
+
+func (m *mountPrefixFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Fs.OpenFile(rel, flag, perm)
+}
+
+func (m *mountPrefixFs) Stat(name string) (os.FileInfo, error) {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Fs.Stat(rel)
+}
+
+func (m *mountPrefixFs) Create(name string) (afero.File, error) {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.Fs.Create(rel)
+}
+
+func (m *mountPrefixFs) Mkdir(name string, perm os.FileMode) error {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return err
+	}
+	return m.Fs.Mkdir(rel, perm)
+}
+
+func (m *mountPrefixFs) MkdirAll(path string, perm os.FileMode) error {
+	rel, err := m.stripPrefix(path)
+	if err != nil {
+		return err
+	}
+	return m.Fs.MkdirAll(rel, perm)
+}
+
+func (m *mountPrefixFs) Remove(name string) error {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return err
+	}
+	return m.Fs.Remove(rel)
+}
+
+func (m *mountPrefixFs) RemoveAll(path string) error {
+	rel, err := m.stripPrefix(path)
+	if err != nil {
+		return err
+	}
+	return m.Fs.RemoveAll(rel)
+}
+
+func (m *mountPrefixFs) Rename(oldname, newname string) error {
+	oldRel, err := m.stripPrefix(oldname)
+	if err != nil {
+		return err
+	}
+	newRel, err := m.stripPrefix(newname)
+	if err != nil {
+		return err
+	}
+	return m.Fs.Rename(oldRel, newRel)
+}
+
+func (m *mountPrefixFs) Chmod(name string, mode os.FileMode) error {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return err
+	}
+	return m.Fs.Chmod(rel, mode)
+}
+
+func (m *mountPrefixFs) Chtimes(name string, atime, mtime time.Time) error {
+	rel, err := m.stripPrefix(name)
+	if err != nil {
+		return err
+	}
+	return m.Fs.Chtimes(rel, atime, mtime)
+}
+
+// RelPath strips whichever layer's root is a prefix of absPath and returns
+// the path relative to it, replacing the separate MakePathRelative calls
+// each caller used to make against GetStaticDirPath/GetThemesDirPath/etc.
+func (ofs *OverlayFs) RelPath(absPath string) (string, error) {
+	for _, l := range ofs.layers {
+		if l.root != "" && strings.HasPrefix(absPath, l.root) {
+			return strings.TrimPrefix(absPath, l.root), nil
+		}
+	}
+	return "", errors.New("can't extract relative path, unknown prefix")
+}
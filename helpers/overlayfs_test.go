@@ -0,0 +1,120 @@
+// Copyright 2015 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestOverlayFsPrecedence(t *testing.T) {
+	base := afero.NewMemMapFs()
+	afero.WriteFile(base, "/site/css/site.css", []byte("site"), 0644)
+	afero.WriteFile(base, "/theme/css/theme.css", []byte("theme"), 0644)
+	afero.WriteFile(base, "/theme/css/site.css", []byte("shadowed"), 0644)
+
+	ofs := NewOverlayFs(base, "/site", []string{"/theme"}, nil)
+
+	b, err := afero.ReadFile(ofs, "css/site.css")
+	if err != nil {
+		t.Fatalf("expected css/site.css to resolve from the site layer: %s", err)
+	}
+	if string(b) != "site" {
+		t.Errorf("css/site.css = %q, want the site layer's content, not the shadowed theme one", string(b))
+	}
+
+	b, err = afero.ReadFile(ofs, "css/theme.css")
+	if err != nil {
+		t.Fatalf("expected css/theme.css to fall through to the theme layer: %s", err)
+	}
+	if string(b) != "theme" {
+		t.Errorf("css/theme.css = %q, want %q", string(b), "theme")
+	}
+}
+
+func TestOverlayFsWritesGoToSiteLayer(t *testing.T) {
+	base := afero.NewMemMapFs()
+	ofs := NewOverlayFs(base, "/site", []string{"/theme"}, nil)
+
+	if err := afero.WriteFile(ofs, "generated.css", []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := afero.Exists(base, "/site/generated.css"); !ok {
+		t.Errorf("expected the write to land in the site layer at /site/generated.css")
+	}
+	if ok, _ := afero.Exists(base, "/theme/generated.css"); ok {
+		t.Errorf("write leaked into the theme layer")
+	}
+}
+
+func TestOverlayFsMount(t *testing.T) {
+	base := afero.NewMemMapFs()
+	afero.WriteFile(base, "/shared/img/logo.png", []byte("logo"), 0644)
+
+	ofs := NewOverlayFs(base, "/site", nil, nil)
+	ofs.Mount(base, Mount{Source: "/shared/img", Target: "static/img"})
+
+	if _, err := ofs.Stat("static/img/logo.png"); err != nil {
+		t.Fatalf("Stat through the mount failed: %s", err)
+	}
+
+	f, err := ofs.Open("static/img/logo.png")
+	if err != nil {
+		t.Fatalf("Open through the mount failed: %s", err)
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "logo" {
+		t.Errorf("read %q through the mount, want %q", string(b), "logo")
+	}
+}
+
+func TestOverlayFsMountDoesNotShadowSite(t *testing.T) {
+	base := afero.NewMemMapFs()
+	afero.WriteFile(base, "/site/static/img/logo.png", []byte("site-logo"), 0644)
+	afero.WriteFile(base, "/shared/img/logo.png", []byte("mount-logo"), 0644)
+
+	ofs := NewOverlayFs(base, "/site", nil, nil)
+	ofs.Mount(base, Mount{Source: "/shared/img", Target: "static/img"})
+
+	b, err := afero.ReadFile(ofs, "static/img/logo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "site-logo" {
+		t.Errorf("static/img/logo.png = %q, want the site layer to take priority over the mount", string(b))
+	}
+}
+
+func TestOverlayFsRelPath(t *testing.T) {
+	ofs := NewOverlayFs(afero.NewMemMapFs(), "/site", []string{"/theme"}, nil)
+
+	rel, err := ofs.RelPath("/theme/css/theme.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel != "/css/theme.css" {
+		t.Errorf("RelPath = %q, want %q", rel, "/css/theme.css")
+	}
+
+	if _, err := ofs.RelPath("/elsewhere/file.css"); err == nil {
+		t.Errorf("expected an error for a path outside every layer")
+	}
+}
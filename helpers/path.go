@@ -74,8 +74,12 @@ var sanitizeRegexp = regexp.MustCompile("[^a-zA-Z0-9./_-]")
 // It does so by creating a Unicode-sanitized string, with the spaces replaced,
 // whilst preserving the original casing of the string.
 // E.g. Social Media -> Social-Media
+//
+// If the "slugify" config key selects a transliteration strategy (e.g.
+// "translit:ru"), the string is first run through it so non-Latin scripts
+// come out as Latin letters; "ascii" instead drops every non-ASCII rune.
 func MakePath(s string) string {
-	return UnicodeSanitize(strings.Replace(strings.TrimSpace(s), " ", "-", -1))
+	return UnicodeSanitize(Slugify(strings.Replace(strings.TrimSpace(s), " ", "-", -1)))
 }
 
 // MakePathSanitized creates a Unicode-sanitized string, with the spaces replaced
@@ -183,13 +187,47 @@ func GetThemesDirPath() string {
 	return dir
 }
 
-func MakeStaticPathRelative(inPath string) (string, error) {
-	staticDir := GetStaticDirPath()
-	themeStaticDir := GetThemesDirPath()
+// Themes returns the configured theme names, in priority order: the
+// "themes" list if set, else the single legacy "theme" key, else none.
+func Themes() []string {
+	if themes := viper.GetStringSlice("themes"); len(themes) > 0 {
+		return themes
+	}
+	if ThemeSet() {
+		return []string{viper.GetString("theme")}
+	}
+	return nil
+}
 
-	return MakePathRelative(inPath, staticDir, themeStaticDir)
+// themeStaticDirs returns the static dir of every configured theme that
+// actually has one, in priority order.
+func themeStaticDirs() []string {
+	var dirs []string
+	for _, t := range Themes() {
+		dir := AbsPathify(filepath.Join(viper.GetString("themesDir"), t, "static"))
+		if ok, _ := DirExists(dir, afero.NewOsFs()); ok {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// StaticOverlayFs returns the OverlayFs backing the site's static files:
+// the site's own static dir, then each configured theme's static dir.
+func StaticOverlayFs() *OverlayFs {
+	return NewOverlayFs(afero.NewOsFs(), GetStaticDirPath(), themeStaticDirs(), nil)
+}
+
+// MakeStaticPathRelative resolves inPath against the static OverlayFs,
+// stripping whichever of the site/theme static layers it came from.
+func MakeStaticPathRelative(inPath string) (string, error) {
+	return StaticOverlayFs().RelPath(inPath)
 }
 
+// MakePathRelative strips whichever of possibleDirectories is a prefix of
+// inPath. New code that deals with overlaid site/theme/module directories
+// should prefer OverlayFs.RelPath, which does the same job without needing
+// the caller to enumerate every directory by hand.
 func MakePathRelative(inPath string, possibleDirectories ...string) (string, error) {
 
 	for _, currentPath := range possibleDirectories {
@@ -306,6 +344,10 @@ func GetRelativePath(path, base string) (final string, err error) {
 	return name, nil
 }
 
+// PaginateAliasPath returns the internal path used for a pagination alias.
+// It only produces the HTMLMetaRefresh stub path; it does not pass the
+// pagination alias to WriteAliases, so no Netlify/nginx/Apache/JSON
+// redirect is emitted for it here.
 func PaginateAliasPath(base string, page int) string {
 	paginatePath := viper.GetString("paginatePath")
 	uglify := viper.GetBool("UglyURLs")
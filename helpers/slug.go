@@ -0,0 +1,159 @@
+// Copyright 2015 The Hugo Authors. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helpers
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/spf13/viper"
+)
+
+// Transliterator turns a string containing arbitrary Unicode script into a
+// string that is safe and readable when used in a URL path, e.g. mapping
+// Cyrillic "Привет" to "privet".
+type Transliterator interface {
+	// Transliterate returns the ASCII-friendly rendering of s. Implementations
+	// are free to return s unchanged for runes they don't recognise; those
+	// runes then go through UnicodeSanitize, which keeps letters and digits
+	// of any script.
+	Transliterate(s string) string
+}
+
+// TransliteratorFunc is an adapter to allow the use of ordinary functions as
+// Transliterators.
+type TransliteratorFunc func(s string) string
+
+// Transliterate calls f(s).
+func (f TransliteratorFunc) Transliterate(s string) string {
+	return f(s)
+}
+
+var (
+	slugTransliteratorsMu sync.RWMutex
+	slugTransliterators   = map[string]Transliterator{}
+)
+
+// RegisterSlugTransliterator makes a Transliterator available under name to
+// the "slugify" config key, e.g. "translit:ru". Registering under a name
+// that already exists replaces the previous entry, so themes and plugins can
+// override the built-ins.
+func RegisterSlugTransliterator(name string, t Transliterator) {
+	slugTransliteratorsMu.Lock()
+	defer slugTransliteratorsMu.Unlock()
+	slugTransliterators[name] = t
+}
+
+func lookupSlugTransliterator(name string) (Transliterator, bool) {
+	slugTransliteratorsMu.RLock()
+	defer slugTransliteratorsMu.RUnlock()
+	t, ok := slugTransliterators[name]
+	return t, ok
+}
+
+func init() {
+	RegisterSlugTransliterator("strict-ascii", TransliteratorFunc(stripNonASCII))
+	RegisterSlugTransliterator("ru", newTableTransliterator(cyrillicRuTable))
+	RegisterSlugTransliterator("uk", newTableTransliterator(cyrillicUkTable))
+	RegisterSlugTransliterator("el", newTableTransliterator(greekTable))
+	RegisterSlugTransliterator("de", newTableTransliterator(germanTable))
+	RegisterSlugTransliterator("tr", newTableTransliterator(turkishTable))
+
+	// Scripts that need more than a rune-to-string table (zh-pinyin, which
+	// is context-sensitive, and ja-romaji, which needs a full kana table)
+	// are deliberately not bundled here to keep this package dependency-free.
+	// Themes/plugins can ship them with e.g. kagome and call
+	// RegisterSlugTransliterator("zh-pinyin", ...) / ("ja-romaji", ...)
+	// during their init().
+}
+
+func stripNonASCII(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r <= unicode.MaxASCII {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// newTableTransliterator builds a Transliterator from a simple rune-to-string
+// replacement table, the approach used by most of the per-language built-ins
+// below. Runes not present in the table are passed through unchanged.
+func newTableTransliterator(table map[rune]string) Transliterator {
+	return TransliteratorFunc(func(s string) string {
+		var b strings.Builder
+		for _, r := range s {
+			if rep, ok := table[r]; ok {
+				b.WriteString(rep)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	})
+}
+
+// slugStrategy resolves the configured "slugify" value to a strategy name and
+// an optional transliterator, e.g. "translit:ru" -> ("translit", ru-table).
+type slugStrategy struct {
+	name           string
+	transliterator Transliterator
+}
+
+// getSlugStrategy reads the "slugify" config key (default, ascii,
+// translit or translit:<lang>) and resolves it to a slugStrategy. Unknown
+// strategies fall back to "default", i.e. today's UnicodeSanitize behaviour.
+func getSlugStrategy() slugStrategy {
+	value := viper.GetString("slugify")
+	if value == "" {
+		value = "default"
+	}
+
+	name := value
+	lang := ""
+	if idx := strings.Index(value, ":"); idx != -1 {
+		name = value[:idx]
+		lang = value[idx+1:]
+	}
+
+	switch name {
+	case "ascii":
+		t, _ := lookupSlugTransliterator("strict-ascii")
+		return slugStrategy{name: name, transliterator: t}
+	case "translit":
+		if lang == "" {
+			lang = "default"
+		}
+		if t, ok := lookupSlugTransliterator(lang); ok {
+			return slugStrategy{name: name, transliterator: t}
+		}
+		return slugStrategy{name: "default"}
+	default:
+		return slugStrategy{name: "default"}
+	}
+}
+
+// Slugify runs s through the transliterator selected by the "slugify" config
+// key, if any. It is called by MakePath before the existing accent-stripping
+// logic so multilingual sites can opt into readable URLs for non-Latin
+// scripts without changing every call site.
+func Slugify(s string) string {
+	strategy := getSlugStrategy()
+	if strategy.transliterator == nil {
+		return s
+	}
+	return strategy.transliterator.Transliterate(s)
+}